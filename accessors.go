@@ -0,0 +1,53 @@
+package h2conn
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+)
+
+// Request returns the *http.Request the Conn was accepted from on the
+// server side, or the request used to dial it on the client side.
+func (c *Conn) Request() *http.Request {
+	return c.request
+}
+
+// RemoteAddr returns the address of the connection's peer, or nil if it is
+// not known.
+func (c *Conn) RemoteAddr() net.Addr {
+	if c.request == nil || c.request.RemoteAddr == "" {
+		return nil
+	}
+	return stringAddr{network: "tcp", value: c.request.RemoteAddr}
+}
+
+// LocalAddr returns the local address of the connection, or nil if it is
+// not known.
+func (c *Conn) LocalAddr() net.Addr {
+	if c.request == nil {
+		return nil
+	}
+	a, _ := c.request.Context().Value(http.LocalAddrContextKey).(net.Addr)
+	return a
+}
+
+// TLS returns the TLS connection state of a connection produced by
+// Server.Accept, or nil if it was not established over TLS. On a Conn
+// returned by Client.Connect it is always nil, since the outgoing request
+// it reads from carries no TLS state.
+func (c *Conn) TLS() *tls.ConnectionState {
+	if c.request == nil {
+		return nil
+	}
+	return c.request.TLS
+}
+
+// stringAddr is a trivial net.Addr for addresses known only as a string,
+// such as http.Request.RemoteAddr.
+type stringAddr struct {
+	network string
+	value   string
+}
+
+func (a stringAddr) Network() string { return a.network }
+func (a stringAddr) String() string  { return a.value }
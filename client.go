@@ -0,0 +1,94 @@
+package h2conn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Client is used for creating a full-duplex connection to a server's handler
+// registered with Accept.
+type Client struct {
+	// Client is the http.Client used to perform the request. If nil,
+	// http.DefaultClient is used. Its Transport must support HTTP/2.
+	Client *http.Client
+
+	// Method is the HTTP method used for the connection. If empty,
+	// http.MethodPost is used.
+	Method string
+
+	// Header is the additional header sent with the request.
+	Header http.Header
+
+	// Protocol, if set, is sent as the ":protocol" pseudo-header of an RFC
+	// 8441 extended CONNECT request, switching Method to http.MethodConnect.
+	// This requires Client's Transport to be an explicit
+	// *golang.org/x/net/http2.Transport: the standard library's built-in
+	// HTTP/2 auto-upgrade rejects ":protocol" as an invalid header field
+	// name before the request ever reaches the wire.
+	Protocol string
+
+	// Observer, if set, is notified of lifecycle events for the Conn
+	// returned by Connect.
+	Observer Observer
+}
+
+// Connect dials url and returns a full-duplex Conn bound to ctx, along with
+// the handshake response. The returned error is non-nil only if the
+// handshake itself failed; once Connect succeeds, transport errors surface
+// through the Conn's Read/Write/Wait.
+func (c Client) Connect(ctx context.Context, url string) (*Conn, *http.Response, error) {
+	method := c.Method
+	if c.Protocol != "" {
+		method = http.MethodConnect
+	} else if method == "" {
+		method = http.MethodPost
+	}
+
+	reader, writer := io.Pipe()
+
+	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("h2conn: create request: %w", err)
+	}
+	if c.Header != nil {
+		req.Header = c.Header.Clone()
+	}
+	if c.Protocol != "" {
+		req.Header.Set(":protocol", c.Protocol)
+	}
+
+	httpClient := c.Client
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("h2conn: request failed: %w", err)
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		resp.Body.Close()
+		return nil, resp, fmt.Errorf("h2conn: unexpected status code %d", resp.StatusCode)
+	}
+
+	conn := newConn(ctx, resp.Body, writer, connOptions{
+		protocol: c.Protocol,
+		request:  req,
+		observer: c.Observer,
+	})
+	if c.Observer != nil {
+		c.Observer.OnAccept(conn)
+		go c.notifyClose(conn)
+	}
+
+	return conn, resp, nil
+}
+
+// notifyClose waits for conn to close and reports it to c.Observer,
+// mirroring Server.untrack on the client side.
+func (c Client) notifyClose(conn *Conn) {
+	<-conn.ctx.Done()
+	c.Observer.OnClose(conn, conn.ctx.Err())
+}
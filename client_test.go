@@ -0,0 +1,93 @@
+package h2conn
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// closeCountObserver counts OnClose calls, embedding NoopObserver for the
+// events this test doesn't care about.
+type closeCountObserver struct {
+	NoopObserver
+
+	mu sync.Mutex
+	n  int
+}
+
+func (o *closeCountObserver) OnClose(c *Conn, err error) {
+	o.mu.Lock()
+	o.n++
+	o.mu.Unlock()
+}
+
+func (o *closeCountObserver) count() int {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.n
+}
+
+func TestClientConnectNotifiesObserverOnClose(t *testing.T) {
+	srv := &H2CServer{}
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := srv.Accept(w, r)
+		if err != nil {
+			t.Errorf("server Accept: %v", err)
+			return
+		}
+		defer conn.Close()
+		buf := make([]byte, 16)
+		conn.Read(buf)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	httpSrv := &http.Server{Handler: srv}
+	go httpSrv.Serve(ln)
+	defer httpSrv.Close()
+
+	observer := &closeCountObserver{}
+	client := Client{
+		Client: &http.Client{
+			Transport: &http2.Transport{
+				AllowHTTP: true,
+				DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+					return net.Dial(network, addr)
+				},
+			},
+		},
+		Observer: observer,
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := client.Connect(ctx, "http://"+ln.Addr().String()+"/")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	if observer.count() != 0 {
+		t.Fatalf("OnClose fired before Close, count = %d", observer.count())
+	}
+
+	if err := conn.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	deadline := time.After(time.Second)
+	for observer.count() == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("Observer.OnClose was not called after client Conn.Close")
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package h2conn
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+// coalescingWriter buffers small writes and flushes them to an underlying
+// writer once the buffer reaches threshold bytes, or delay has elapsed
+// since the first buffered write, whichever comes first.
+type coalescingWriter struct {
+	w         io.Writer
+	threshold int
+	delay     time.Duration
+
+	mu    sync.Mutex
+	buf   []byte
+	timer *time.Timer
+}
+
+func newCoalescingWriter(w io.Writer, threshold int, delay time.Duration) *coalescingWriter {
+	return &coalescingWriter{w: w, threshold: threshold, delay: delay}
+}
+
+// Write appends p to the buffer, flushing immediately if the buffer has
+// reached threshold bytes.
+func (c *coalescingWriter) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.buf = append(c.buf, p...)
+	if c.timer == nil && c.delay > 0 {
+		c.timer = time.AfterFunc(c.delay, func() { _ = c.Flush() })
+	}
+	if c.threshold > 0 && len(c.buf) < c.threshold {
+		return len(p), nil
+	}
+	if err := c.flushLocked(); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// Flush writes any buffered bytes to the underlying writer immediately.
+func (c *coalescingWriter) Flush() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.flushLocked()
+}
+
+func (c *coalescingWriter) flushLocked() error {
+	if c.timer != nil {
+		c.timer.Stop()
+		c.timer = nil
+	}
+	if len(c.buf) == 0 {
+		return nil
+	}
+	buf := c.buf
+	c.buf = nil
+	_, err := c.w.Write(buf)
+	return err
+}
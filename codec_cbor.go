@@ -0,0 +1,16 @@
+package h2conn
+
+import (
+	"io"
+
+	"github.com/fxamacker/cbor/v2"
+)
+
+// CBORCodec frames each message as a single CBOR data item, using the
+// streaming cbor.Encoder/cbor.Decoder.
+var CBORCodec Codec = cborCodec{}
+
+type cborCodec struct{}
+
+func (cborCodec) NewEncoder(w io.Writer) Encoder { return cbor.NewEncoder(w) }
+func (cborCodec) NewDecoder(r io.Reader) Decoder { return cbor.NewDecoder(r) }
@@ -0,0 +1,69 @@
+package h2conn
+
+import (
+	"bufio"
+	"encoding/binary"
+	"fmt"
+	"io"
+
+	"google.golang.org/protobuf/proto"
+)
+
+// ProtobufCodec frames each message as a varint length followed by the
+// marshaled protobuf payload. Messages must implement proto.Message.
+var ProtobufCodec Codec = protobufCodec{}
+
+type protobufCodec struct{}
+
+func (protobufCodec) NewEncoder(w io.Writer) Encoder {
+	return &protobufEncoder{w: w}
+}
+
+func (protobufCodec) NewDecoder(r io.Reader) Decoder {
+	br, ok := r.(*bufio.Reader)
+	if !ok {
+		br = bufio.NewReader(r)
+	}
+	return &protobufDecoder{r: br}
+}
+
+type protobufEncoder struct{ w io.Writer }
+
+func (e *protobufEncoder) Encode(v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("h2conn: ProtobufCodec requires proto.Message, got %T", v)
+	}
+	b, err := proto.Marshal(msg)
+	if err != nil {
+		return err
+	}
+	header := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, uint64(len(b)))
+	if _, err := e.w.Write(header[:n]); err != nil {
+		return err
+	}
+	_, err = e.w.Write(b)
+	return err
+}
+
+type protobufDecoder struct{ r *bufio.Reader }
+
+func (d *protobufDecoder) Decode(v interface{}) error {
+	msg, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("h2conn: ProtobufCodec requires proto.Message, got %T", v)
+	}
+	length, err := binary.ReadUvarint(d.r)
+	if err != nil {
+		return err
+	}
+	if length > maxMessageSize {
+		return fmt.Errorf("h2conn: message length %d exceeds maxMessageSize (%d)", length, maxMessageSize)
+	}
+	buf := make([]byte, length)
+	if _, err := io.ReadFull(d.r, buf); err != nil {
+		return err
+	}
+	return proto.Unmarshal(buf, msg)
+}
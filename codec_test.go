@@ -0,0 +1,36 @@
+package h2conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+)
+
+func TestLengthPrefixedDecoderRejectsOversizedLength(t *testing.T) {
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, maxMessageSize+1)
+
+	dec := LengthPrefixedCodec.NewDecoder(bytes.NewReader(header))
+
+	var out []byte
+	if err := dec.Decode(&out); err == nil {
+		t.Fatal("expected Decode to reject a length exceeding maxMessageSize, got nil error")
+	}
+}
+
+func TestLengthPrefixedCodecRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	enc := LengthPrefixedCodec.NewEncoder(&buf)
+	if err := enc.Encode([]byte("hello")); err != nil {
+		t.Fatalf("Encode: %v", err)
+	}
+
+	dec := LengthPrefixedCodec.NewDecoder(&buf)
+	var out []byte
+	if err := dec.Decode(&out); err != nil {
+		t.Fatalf("Decode: %v", err)
+	}
+	if string(out) != "hello" {
+		t.Fatalf("got %q, want %q", out, "hello")
+	}
+}
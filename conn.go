@@ -0,0 +1,208 @@
+package h2conn
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Conn is client/server symmetric connection.
+// It implements the io.Reader/io.Writer/io.Closer to read/write or close the
+// connection to the other side.
+type Conn struct {
+	io.Reader
+	io.Writer
+	io.Closer
+
+	ctx      context.Context
+	cancel   context.CancelFunc
+	protocol string
+	request  *http.Request
+	observer Observer
+
+	shutdownOnce sync.Once
+	shutdownCh   chan struct{}
+
+	codecMu   sync.Mutex
+	codec     Codec
+	enc       Encoder
+	dec       Decoder
+	coalescer *coalescingWriter
+}
+
+// connOptions carries the optional, per-connection configuration threaded
+// through by Accept/Connect into newConn.
+type connOptions struct {
+	protocol string
+	request  *http.Request
+	observer Observer
+}
+
+// newConn creates a Conn that reads from r and writes to w, and is bound to
+// ctx: once ctx is done, the connection is considered closed.
+func newConn(ctx context.Context, r io.Reader, w io.WriteCloser, opts connOptions) *Conn {
+	ctx, cancel := context.WithCancel(ctx)
+	return &Conn{
+		Reader:     r,
+		Writer:     w,
+		Closer:     w,
+		ctx:        ctx,
+		cancel:     cancel,
+		protocol:   opts.protocol,
+		request:    opts.request,
+		observer:   opts.observer,
+		shutdownCh: make(chan struct{}),
+	}
+}
+
+// Protocol returns the RFC 8441 extended CONNECT protocol this connection
+// was negotiated with (e.g. "websocket"), or "" if it was established as a
+// plain h2conn connection.
+func (c *Conn) Protocol() string {
+	return c.protocol
+}
+
+// Wait blocks until the connection is closed, returning the reason it was
+// closed, either because the remote side closed it or because ctx was done.
+// On a Conn returned by Client.Connect, the connection counts as closed only
+// once Close is called or the ctx passed to Connect is done; the peer ending
+// its side shows up only as io.EOF from Read.
+func (c *Conn) Wait(ctx context.Context) error {
+	select {
+	case <-c.ctx.Done():
+		return c.ctx.Err()
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close closes the connection, releasing any resources associated with it.
+func (c *Conn) Close() error {
+	c.cancel()
+	return c.Closer.Close()
+}
+
+// CloseWrite is a local-only write-fencing signal, used by Server.Shutdown:
+// it closes the write side of the connection, without canceling ctx, so a
+// handler still Writing to c fails its next Write with io.ErrClosedPipe.
+// On a Conn produced by Server.Accept it does not touch the wire and is not
+// visible to the peer — a handler blocked reading from c, or otherwise not
+// currently writing, gets no indication anything happened. A handler that
+// wants to notice a graceful shutdown while blocked in Read should instead
+// select on ShutdownRequested and return. On a Conn returned by
+// Client.Connect it closes the request body, which ends the stream's
+// request side and is seen by the peer as end of input.
+func (c *Conn) CloseWrite() error {
+	c.shutdownOnce.Do(func() { close(c.shutdownCh) })
+	return c.Closer.Close()
+}
+
+// ShutdownRequested returns a channel that is closed when CloseWrite is
+// called on c (i.e. when Server.Shutdown has asked this connection to wind
+// down). A handler can select on it alongside its own Read/Write loop to
+// notice a graceful shutdown and return, ending the underlying HTTP/2
+// stream, instead of only finding out the next time it happens to Write.
+func (c *Conn) ShutdownRequested() <-chan struct{} {
+	return c.shutdownCh
+}
+
+// Read reads from the connection, reporting the read to the Server's or
+// Client's Observer, if any.
+func (c *Conn) Read(p []byte) (int, error) {
+	n, err := c.Reader.Read(p)
+	if c.observer != nil {
+		if n > 0 {
+			c.observer.OnRead(c, n)
+		}
+		if err != nil && err != io.EOF {
+			c.observer.OnError(c, err)
+		}
+	}
+	return n, err
+}
+
+// Write writes p to the connection, routing through the coalescing buffer
+// installed by SetWriteCoalescing, if any.
+func (c *Conn) Write(p []byte) (int, error) {
+	c.codecMu.Lock()
+	w := io.Writer(c.Writer)
+	if c.coalescer != nil {
+		w = c.coalescer
+	}
+	c.codecMu.Unlock()
+
+	return w.Write(p)
+}
+
+// SetCodec installs codec for use by WriteMessage and ReadMessage. It is
+// not safe for concurrent use with WriteMessage/ReadMessage, and should
+// typically be called once, right after Accept/Connect.
+func (c *Conn) SetCodec(codec Codec) {
+	c.codecMu.Lock()
+	defer c.codecMu.Unlock()
+
+	c.codec = codec
+	w := io.Writer(c.Writer)
+	if c.coalescer != nil {
+		w = c.coalescer
+	}
+	c.enc = codec.NewEncoder(w)
+	c.dec = codec.NewDecoder(c.Reader)
+}
+
+// WriteMessage encodes v with the codec installed by SetCodec and writes
+// it as a single message.
+func (c *Conn) WriteMessage(v interface{}) error {
+	c.codecMu.Lock()
+	enc := c.enc
+	c.codecMu.Unlock()
+
+	if enc == nil {
+		return fmt.Errorf("h2conn: no codec set, call SetCodec first")
+	}
+	return enc.Encode(v)
+}
+
+// ReadMessage decodes the next message, as framed by the codec installed
+// by SetCodec, into v.
+func (c *Conn) ReadMessage(v interface{}) error {
+	c.codecMu.Lock()
+	dec := c.dec
+	c.codecMu.Unlock()
+
+	if dec == nil {
+		return fmt.Errorf("h2conn: no codec set, call SetCodec first")
+	}
+	return dec.Decode(v)
+}
+
+// SetWriteCoalescing buffers bytes written via Write/WriteMessage, flushing
+// them to the connection once threshold bytes have accumulated or delay
+// has elapsed since the first buffered byte, whichever happens first. This
+// avoids generating one HTTP/2 DATA frame per small Write on high-rate
+// message workloads. Call Flush to force buffered bytes out immediately.
+func (c *Conn) SetWriteCoalescing(threshold int, delay time.Duration) {
+	c.codecMu.Lock()
+	defer c.codecMu.Unlock()
+
+	c.coalescer = newCoalescingWriter(c.Writer, threshold, delay)
+	if c.codec != nil {
+		c.enc = c.codec.NewEncoder(c.coalescer)
+	}
+}
+
+// Flush writes any bytes buffered by SetWriteCoalescing to the connection
+// immediately. It is a no-op if write coalescing is not enabled.
+func (c *Conn) Flush() error {
+	c.codecMu.Lock()
+	cw := c.coalescer
+	c.codecMu.Unlock()
+
+	if cw == nil {
+		return nil
+	}
+	return cw.Flush()
+}
@@ -0,0 +1,156 @@
+package h2conn
+
+import (
+	"bufio"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+
+	"golang.org/x/net/http2"
+)
+
+// h2cPreface is the client connection preface that precedes a prior-knowledge
+// h2c request, as defined in RFC 7540 section 3.4. The net/http server has
+// already consumed "PRI * HTTP/2.0\r\n\r\n" (parsed as a request line
+// followed by an empty header block) by the time ServeHTTP is called, so
+// only the remainder is left to verify.
+const h2cPreface = "SM\r\n\r\n"
+
+// H2CServer accepts h2conn connections over plain HTTP, without TLS, by
+// speaking cleartext HTTP/2 (h2c) as described in RFC 7540 section 3.2 and
+// 3.4. It supports both the prior-knowledge preface and the HTTP/1.1
+// "Upgrade: h2c" handshake, and should be registered as the http.Handler of
+// an http.Server whose TLSConfig is left unset.
+type H2CServer struct {
+	// Server is the h2conn server configuration used once the connection
+	// has been upgraded to HTTP/2.
+	Server
+
+	// Handler is invoked for every stream on the upgraded connection, the
+	// same as the http.Handler passed to http2.Server.ServeConn.
+	Handler http.Handler
+}
+
+// ServeHTTP implements http.Handler. It detects h2c connections and hands
+// them off to an http2.Server, so that subsequent requests on the connection
+// reach Handler as ordinary HTTP/2 requests (and can in turn call Accept).
+func (s *H2CServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h2Server := &http2.Server{}
+
+	if r.Method == "PRI" && r.URL.Path == "*" && r.Proto == "HTTP/2.0" {
+		s.servePriorKnowledge(h2Server, w, r)
+		return
+	}
+
+	if isH2CUpgrade(r) {
+		s.serveUpgrade(h2Server, w, r)
+		return
+	}
+
+	http.Error(w, "h2c: not a cleartext HTTP/2 request", http.StatusBadRequest)
+}
+
+// servePriorKnowledge handles the RFC 7540 section 3.4 entry path: the
+// client has already sent "PRI * HTTP/2.0\r\n\r\nSM\r\n\r\n" and expects the
+// connection to be treated as HTTP/2 from here on.
+func (s *H2CServer) servePriorKnowledge(h2Server *http2.Server, w http.ResponseWriter, r *http.Request) {
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "h2c: connection does not support hijacking", http.StatusInternalServerError)
+		return
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("h2c: hijack failed: %s", err), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	preface := make([]byte, len(h2cPreface))
+	if _, err := io.ReadFull(buf, preface); err != nil || string(preface) != h2cPreface {
+		return
+	}
+
+	h2Server.ServeConn(newBufConn(conn, buf.Reader), &http2.ServeConnOpts{
+		Handler: s.Handler,
+		// We've already consumed the client preface above, so tell
+		// http2.Server not to expect it again off the wire.
+		SawClientPreface: true,
+	})
+}
+
+// serveUpgrade handles the RFC 7540 section 3.2 entry path: an HTTP/1.1
+// request carrying "Upgrade: h2c" and a base64url-encoded HTTP2-Settings
+// payload. On success the connection is switched to HTTP/2 and the upgrade
+// request itself is redelivered as stream 1.
+func (s *H2CServer) serveUpgrade(h2Server *http2.Server, w http.ResponseWriter, r *http.Request) {
+	settings, err := base64.RawURLEncoding.DecodeString(r.Header.Get("HTTP2-Settings"))
+	if err != nil || len(settings)%6 != 0 {
+		http.Error(w, "h2c: invalid HTTP2-Settings header", http.StatusBadRequest)
+		return
+	}
+
+	hijacker, ok := w.(http.Hijacker)
+	if !ok {
+		http.Error(w, "h2c: connection does not support hijacking", http.StatusInternalServerError)
+		return
+	}
+
+	conn, buf, err := hijacker.Hijack()
+	if err != nil {
+		http.Error(w, fmt.Sprintf("h2c: hijack failed: %s", err), http.StatusInternalServerError)
+		return
+	}
+	defer conn.Close()
+
+	if _, err := buf.WriteString("HTTP/1.1 101 Switching Protocols\r\nConnection: Upgrade\r\nUpgrade: h2c\r\n\r\n"); err != nil || buf.Flush() != nil {
+		return
+	}
+
+	h2Server.ServeConn(newBufConn(conn, buf.Reader), &http2.ServeConnOpts{
+		Handler:        s.Handler,
+		Settings:       settings,
+		UpgradeRequest: r,
+	})
+}
+
+// bufConn wraps a hijacked net.Conn so that Read first drains any bytes the
+// http.Server already buffered into br (e.g. a client's initial SETTINGS
+// frame sent back-to-back with the h2c preface) before falling through to
+// fresh reads off the raw connection, once the buffer is empty.
+type bufConn struct {
+	net.Conn
+	br *bufio.Reader
+}
+
+func newBufConn(conn net.Conn, br *bufio.Reader) net.Conn {
+	return &bufConn{Conn: conn, br: br}
+}
+
+func (c *bufConn) Read(p []byte) (int, error) {
+	return c.br.Read(p)
+}
+
+// isH2CUpgrade reports whether r is an HTTP/1.1 request asking to upgrade
+// the connection to h2c, per RFC 7540 section 3.2.
+func isH2CUpgrade(r *http.Request) bool {
+	return strings.EqualFold(r.Header.Get("Upgrade"), "h2c") &&
+		tokenListContains(r.Header.Get("Connection"), "Upgrade") &&
+		tokenListContains(r.Header.Get("Connection"), "HTTP2-Settings") &&
+		r.Header.Get("HTTP2-Settings") != ""
+}
+
+// tokenListContains reports whether the comma-separated header value list
+// contains token, ignoring case and surrounding whitespace.
+func tokenListContains(list, token string) bool {
+	for _, v := range strings.Split(list, ",") {
+		if strings.EqualFold(strings.TrimSpace(v), token) {
+			return true
+		}
+	}
+	return false
+}
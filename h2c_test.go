@@ -0,0 +1,46 @@
+package h2conn
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// fakeConn is a minimal net.Conn backed by a byte slice, used only to
+// verify bufConn's draining behavior.
+type fakeConn struct {
+	io.Reader
+}
+
+func (fakeConn) Write(p []byte) (int, error)      { return len(p), nil }
+func (fakeConn) Close() error                     { return nil }
+func (fakeConn) LocalAddr() net.Addr              { return nil }
+func (fakeConn) RemoteAddr() net.Addr             { return nil }
+func (fakeConn) SetDeadline(time.Time) error      { return nil }
+func (fakeConn) SetReadDeadline(time.Time) error  { return nil }
+func (fakeConn) SetWriteDeadline(time.Time) error { return nil }
+
+func TestBufConnDrainsBufferedBytes(t *testing.T) {
+	raw := fakeConn{Reader: bytes.NewReader([]byte("already-on-the-wire"))}
+	br := bufio.NewReader(raw)
+
+	// Simulate the http.Server having already buffered everything past the
+	// preface (e.g. a client that sent its SETTINGS frame back-to-back with
+	// the h2c preface in the same TCP segment).
+	if _, err := br.Peek(1); err != nil {
+		t.Fatalf("Peek: %v", err)
+	}
+
+	bc := newBufConn(raw, br)
+
+	got := make([]byte, len("already-on-the-wire"))
+	if _, err := io.ReadFull(bc, got); err != nil {
+		t.Fatalf("ReadFull: %v", err)
+	}
+	if string(got) != "already-on-the-wire" {
+		t.Fatalf("got %q, want the bytes buffered by the http.Server", got)
+	}
+}
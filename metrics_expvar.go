@@ -0,0 +1,54 @@
+package h2conn
+
+import (
+	"expvar"
+	"sync/atomic"
+	"time"
+)
+
+// ExpvarObserver is an Observer that publishes live connection counts and
+// cumulative byte counters via expvar.
+type ExpvarObserver struct {
+	accepted  int64
+	live      int64
+	bytesRead int64
+	bytesWrit int64
+}
+
+var _ Observer = (*ExpvarObserver)(nil)
+
+// NewExpvarObserver creates an ExpvarObserver and publishes its counters
+// under name in the default expvar registry.
+func NewExpvarObserver(name string) *ExpvarObserver {
+	o := &ExpvarObserver{}
+	expvar.Publish(name, expvar.Func(func() interface{} {
+		return map[string]int64{
+			"accepted":      atomic.LoadInt64(&o.accepted),
+			"live":          atomic.LoadInt64(&o.live),
+			"bytes_read":    atomic.LoadInt64(&o.bytesRead),
+			"bytes_written": atomic.LoadInt64(&o.bytesWrit),
+		}
+	}))
+	return o
+}
+
+func (o *ExpvarObserver) OnAccept(c *Conn) {
+	atomic.AddInt64(&o.accepted, 1)
+	atomic.AddInt64(&o.live, 1)
+}
+
+func (o *ExpvarObserver) OnClose(c *Conn, err error) {
+	atomic.AddInt64(&o.live, -1)
+}
+
+func (o *ExpvarObserver) OnRead(c *Conn, n int) {
+	atomic.AddInt64(&o.bytesRead, int64(n))
+}
+
+func (o *ExpvarObserver) OnWrite(c *Conn, n int) {
+	atomic.AddInt64(&o.bytesWrit, int64(n))
+}
+
+func (o *ExpvarObserver) OnFlush(c *Conn, d time.Duration) {}
+
+func (o *ExpvarObserver) OnError(c *Conn, err error) {}
@@ -0,0 +1,95 @@
+package h2conn
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// PrometheusCollector is an Observer that is also a prometheus.Collector,
+// exposing live connection count, accepted/closed totals, byte counters,
+// and flush latency as Prometheus metrics.
+type PrometheusCollector struct {
+	accepted     prometheus.Counter
+	closed       prometheus.Counter
+	live         prometheus.Gauge
+	bytesRead    prometheus.Counter
+	bytesWritten prometheus.Counter
+	flushLatency prometheus.Histogram
+	errors       prometheus.Counter
+}
+
+var (
+	_ Observer             = (*PrometheusCollector)(nil)
+	_ prometheus.Collector = (*PrometheusCollector)(nil)
+)
+
+// NewPrometheusCollector creates a PrometheusCollector. Register it with a
+// prometheus.Registerer, and pass it as a Server's or Client's Observer.
+func NewPrometheusCollector() *PrometheusCollector {
+	return &PrometheusCollector{
+		accepted: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "h2conn", Name: "accepted_total", Help: "Total number of accepted connections.",
+		}),
+		closed: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "h2conn", Name: "closed_total", Help: "Total number of closed connections.",
+		}),
+		live: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "h2conn", Name: "live_connections", Help: "Number of currently live connections.",
+		}),
+		bytesRead: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "h2conn", Name: "bytes_read_total", Help: "Total number of bytes read.",
+		}),
+		bytesWritten: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "h2conn", Name: "bytes_written_total", Help: "Total number of bytes written.",
+		}),
+		flushLatency: prometheus.NewHistogram(prometheus.HistogramOpts{
+			Namespace: "h2conn", Name: "flush_latency_seconds", Help: "Latency of flushing a write to the connection.",
+		}),
+		errors: prometheus.NewCounter(prometheus.CounterOpts{
+			Namespace: "h2conn", Name: "errors_total", Help: "Total number of connection errors.",
+		}),
+	}
+}
+
+func (c *PrometheusCollector) OnAccept(conn *Conn) {
+	c.accepted.Inc()
+	c.live.Inc()
+}
+
+func (c *PrometheusCollector) OnClose(conn *Conn, err error) {
+	c.closed.Inc()
+	c.live.Dec()
+}
+
+func (c *PrometheusCollector) OnRead(conn *Conn, n int) {
+	c.bytesRead.Add(float64(n))
+}
+
+func (c *PrometheusCollector) OnWrite(conn *Conn, n int) {
+	c.bytesWritten.Add(float64(n))
+}
+
+func (c *PrometheusCollector) OnFlush(conn *Conn, d time.Duration) {
+	c.flushLatency.Observe(d.Seconds())
+}
+
+func (c *PrometheusCollector) OnError(conn *Conn, err error) {
+	c.errors.Inc()
+}
+
+// Describe implements prometheus.Collector.
+func (c *PrometheusCollector) Describe(ch chan<- *prometheus.Desc) {
+	prometheus.DescribeByCollect(c, ch)
+}
+
+// Collect implements prometheus.Collector.
+func (c *PrometheusCollector) Collect(ch chan<- prometheus.Metric) {
+	c.accepted.Collect(ch)
+	c.closed.Collect(ch)
+	c.live.Collect(ch)
+	c.bytesRead.Collect(ch)
+	c.bytesWritten.Collect(ch)
+	c.flushLatency.Collect(ch)
+	c.errors.Collect(ch)
+}
@@ -0,0 +1,49 @@
+package h2conn
+
+import "time"
+
+// Observer receives lifecycle events for Conns produced by a Server or
+// Client. Implementations should return quickly, since OnRead, OnWrite,
+// OnFlush and OnError are called synchronously on the connection's
+// read/write path. OnClose is called from a separate goroutine.
+type Observer interface {
+	// OnAccept is called once a Conn has been established, right before
+	// it is returned to the caller of Accept or Connect.
+	OnAccept(c *Conn)
+
+	// OnClose is called once a Conn is closed, as seen by Conn.Wait. err is
+	// the reason it was closed, as returned by Conn.Wait.
+	OnClose(c *Conn, err error)
+
+	// OnRead is called after a successful Read of n bytes.
+	OnRead(c *Conn, n int)
+
+	// OnWrite is called after a Write of n bytes reaches the wire. It is
+	// called only for Conns produced by Server.Accept; writes on a Conn
+	// returned by Client.Connect are not reported.
+	OnWrite(c *Conn, n int)
+
+	// OnFlush is called after every flush of a Write to the wire, with the
+	// time it took to write and flush. Like OnWrite, it is called only for
+	// Conns produced by Server.Accept.
+	OnFlush(c *Conn, d time.Duration)
+
+	// OnError is called whenever a Read fails with an error other than
+	// io.EOF, and whenever a Write fails on a Conn produced by
+	// Server.Accept. Write errors on a Conn returned by Client.Connect are
+	// not reported.
+	OnError(c *Conn, err error)
+}
+
+// NoopObserver implements Observer with no-op methods, so other Observers
+// can embed it and override only the events they care about.
+type NoopObserver struct{}
+
+func (NoopObserver) OnAccept(c *Conn)                 {}
+func (NoopObserver) OnClose(c *Conn, err error)       {}
+func (NoopObserver) OnRead(c *Conn, n int)            {}
+func (NoopObserver) OnWrite(c *Conn, n int)           {}
+func (NoopObserver) OnFlush(c *Conn, d time.Duration) {}
+func (NoopObserver) OnError(c *Conn, err error)       {}
+
+var _ Observer = NoopObserver{}
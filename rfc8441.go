@@ -0,0 +1,33 @@
+package h2conn
+
+import (
+	"net/http"
+
+	"golang.org/x/net/http2"
+)
+
+// extendedConnectProtocol returns the RFC 8441 ":protocol" of r, or "" if r
+// is not an extended CONNECT request (e.g. a WebSocket-over-HTTP/2 dial).
+func extendedConnectProtocol(r *http.Request) string {
+	if r.Method != http.MethodConnect {
+		return ""
+	}
+	return r.Header.Get(":protocol")
+}
+
+// ConfigureServer enables RFC 8441 extended CONNECT (SETTINGS_ENABLE_CONNECT_PROTOCOL)
+// on an HTTP/2-over-TLS server, so that Accept can receive extended CONNECT
+// requests carrying a ":protocol" such as "websocket". Call it before the
+// server starts serving, in place of http2.ConfigureServer.
+//
+// golang.org/x/net/http2 advertises SETTINGS_ENABLE_CONNECT_PROTOCOL itself;
+// there is no http2.Server field to toggle it. Later releases (x/net >=
+// v0.35.0) disable it by default and only honor it when the process is run
+// with GODEBUG=http2xconnect=1 — a per-process, init-time switch this
+// package cannot flip on a caller's behalf. go.mod pins x/net to the last
+// release that still advertises it unconditionally; if you upgrade x/net
+// past that, extended CONNECT will need GODEBUG=http2xconnect=1 set in the
+// server's environment to keep working.
+func ConfigureServer(s *http.Server) error {
+	return http2.ConfigureServer(s, &http2.Server{})
+}
@@ -0,0 +1,122 @@
+package h2conn
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// selfSignedTLSConfig returns a minimal TLS config backed by a freshly
+// generated self-signed certificate, for use by an in-process HTTP/2-over-TLS
+// test server.
+func selfSignedTLSConfig(t *testing.T) *tls.Config {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("GenerateKey: %v", err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "127.0.0.1"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("CreateCertificate: %v", err)
+	}
+	cert := tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+
+	return &tls.Config{Certificates: []tls.Certificate{cert}}
+}
+
+// TestExtendedConnectEndToEnd dials a real RFC 8441 extended CONNECT request
+// (Client{Protocol: "websocket"}) against a server configured with
+// ConfigureServer, over an actual TLS+HTTP/2 connection, and confirms data
+// flows both ways.
+func TestExtendedConnectEndToEnd(t *testing.T) {
+	tlsConfig := selfSignedTLSConfig(t)
+
+	httpSrv := &http.Server{
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			conn, err := Accept(w, r)
+			if err != nil {
+				t.Errorf("server Accept: %v", err)
+				http.Error(w, err.Error(), http.StatusInternalServerError)
+				return
+			}
+			defer conn.Close()
+
+			if got := conn.Protocol(); got != "websocket" {
+				t.Errorf("server Conn.Protocol() = %q, want %q", got, "websocket")
+			}
+
+			buf := make([]byte, 64)
+			n, err := conn.Read(buf)
+			if err != nil {
+				t.Errorf("server Read: %v", err)
+				return
+			}
+			if _, err := conn.Write(buf[:n]); err != nil {
+				t.Errorf("server Write: %v", err)
+			}
+		}),
+	}
+	httpSrv.TLSConfig = tlsConfig
+	if err := ConfigureServer(httpSrv); err != nil {
+		t.Fatalf("ConfigureServer: %v", err)
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	tlsLn := tls.NewListener(ln, httpSrv.TLSConfig)
+	go httpSrv.Serve(tlsLn)
+	defer httpSrv.Close()
+
+	client := Client{
+		Client: &http.Client{
+			Transport: &http2.Transport{
+				TLSClientConfig: &tls.Config{InsecureSkipVerify: true},
+			},
+		},
+		Protocol: "websocket",
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, resp, err := client.Connect(ctx, "https://"+ln.Addr().String()+"/")
+	if err != nil {
+		t.Fatalf("Connect: %v (status %v)", err, resp)
+	}
+	defer conn.Close()
+
+	if _, err := conn.Write([]byte("hello")); err != nil {
+		t.Fatalf("client Write: %v", err)
+	}
+	buf := make([]byte, 64)
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatalf("client Read: %v", err)
+	}
+	if string(buf[:n]) != "hello" {
+		t.Fatalf("got %q, want %q", buf[:n], "hello")
+	}
+}
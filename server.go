@@ -1,9 +1,12 @@
 package h2conn
 
 import (
+	"context"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"time"
 )
 
 // ErrHTTP2NotSupported is returned by Accept if the client connection does not
@@ -11,10 +14,25 @@ import (
 // The server than can response to the client with an HTTP1.1 as he wishes.
 var ErrHTTP2NotSupported = fmt.Errorf("HTTP2 not supported")
 
+// ErrServerClosed is returned by Accept after Shutdown or Close has been
+// called on the Server, mirroring http.ErrServerClosed.
+var ErrServerClosed = fmt.Errorf("h2conn: Server closed")
+
 // Server can "accept" an http2 connection to obtain a read/write object
 // for full duplex communication with a client.
 type Server struct {
+	// StatusCode is the HTTP status Accept responds with on a successful
+	// upgrade. The zero value means http.StatusOK.
 	StatusCode int
+
+	// Observer, if set, is notified of lifecycle events for every Conn
+	// produced by Accept.
+	Observer Observer
+
+	mu     sync.Mutex
+	closed bool
+	conns  map[*Conn]struct{}
+	wg     sync.WaitGroup
 }
 
 var defaultUpgrader = Server{
@@ -32,15 +50,15 @@ func Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
 //
 // Usage:
 //
-//      func (w http.ResponseWriter, r *http.Request) {
-//          conn, err := h2conn.Accept(w, r)
-//          if err != nil {
-//		        log.Printf("Failed creating http2 connection: %s", err)
-//		        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-//		        return
-//	        }
-//          // use conn
-//      }
+//	     func (w http.ResponseWriter, r *http.Request) {
+//	         conn, err := h2conn.Accept(w, r)
+//	         if err != nil {
+//			        log.Printf("Failed creating http2 connection: %s", err)
+//			        http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+//			        return
+//		        }
+//	         // use conn
+//	     }
 func (u *Server) Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
 	if !r.ProtoAtLeast(2, 0) {
 		return nil, ErrHTTP2NotSupported
@@ -50,25 +68,150 @@ func (u *Server) Accept(w http.ResponseWriter, r *http.Request) (*Conn, error) {
 		return nil, ErrHTTP2NotSupported
 	}
 
-	c := newConn(r.Context(), r.Body, &flushWrite{w: w, f: flusher})
+	u.mu.Lock()
+	if u.closed {
+		u.mu.Unlock()
+		return nil, ErrServerClosed
+	}
+	if u.conns == nil {
+		u.conns = make(map[*Conn]struct{})
+	}
+	u.wg.Add(1)
+	u.mu.Unlock()
 
-	w.WriteHeader(u.StatusCode)
+	fw := &flushWrite{w: w, f: flusher, observer: u.Observer}
+	c := newConn(r.Context(), r.Body, fw, connOptions{
+		protocol: extendedConnectProtocol(r),
+		request:  r,
+		observer: u.Observer,
+	})
+	fw.conn = c
+
+	u.mu.Lock()
+	u.conns[c] = struct{}{}
+	u.mu.Unlock()
+
+	go u.untrack(c)
+
+	if u.Observer != nil {
+		u.Observer.OnAccept(c)
+	}
+
+	statusCode := u.StatusCode
+	if statusCode == 0 {
+		statusCode = http.StatusOK
+	}
+	w.WriteHeader(statusCode)
 	flusher.Flush()
 
 	return c, nil
 }
 
+// untrack waits for c to close and removes it from the live-connection
+// tracker, releasing the wg count added for it in Accept.
+func (u *Server) untrack(c *Conn) {
+	<-c.ctx.Done()
+	if u.Observer != nil {
+		u.Observer.OnClose(c, c.ctx.Err())
+	}
+	u.mu.Lock()
+	delete(u.conns, c)
+	u.mu.Unlock()
+	u.wg.Done()
+}
+
+// Shutdown stops the Server from accepting new connections — subsequent
+// calls to Accept return ErrServerClosed — asks every live connection to
+// wind down via CloseWrite/ShutdownRequested, and blocks until every
+// connection has drained on its own (its handler noticed and returned) or
+// ctx is done, whichever happens first. Unlike Close, it does not
+// force-terminate connections still in flight.
+//
+// This signal is local to the process and not visible to the peer: a
+// handler notices it either the next time it Writes (which then fails
+// with io.ErrClosedPipe) or, if it selects on Conn.ShutdownRequested
+// alongside its Read loop, as soon as Shutdown is called. A handler that
+// only Reads and never checks ShutdownRequested will not drain until its
+// peer stops sending or ctx expires.
+func (u *Server) Shutdown(ctx context.Context) error {
+	u.mu.Lock()
+	u.closed = true
+	for c := range u.conns {
+		c.CloseWrite()
+	}
+	u.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		u.wg.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Close stops the Server from accepting new connections and immediately
+// force-terminates every live connection, without waiting for them to
+// drain.
+func (u *Server) Close() error {
+	u.mu.Lock()
+	u.closed = true
+	conns := u.conns
+	u.conns = nil
+	u.mu.Unlock()
+
+	for c := range conns {
+		c.Close()
+	}
+	return nil
+}
+
 type flushWrite struct {
 	w io.Writer
 	f http.Flusher
+
+	conn     *Conn
+	observer Observer
+
+	mu     sync.Mutex
+	closed bool
 }
 
 func (w *flushWrite) Write(data []byte) (int, error) {
+	w.mu.Lock()
+	closed := w.closed
+	w.mu.Unlock()
+	if closed {
+		return 0, io.ErrClosedPipe
+	}
+
+	start := time.Now()
 	n, err := w.w.Write(data)
 	w.f.Flush()
+
+	if w.observer != nil {
+		w.observer.OnFlush(w.conn, time.Since(start))
+		w.observer.OnWrite(w.conn, n)
+		if err != nil {
+			w.observer.OnError(w.conn, err)
+		}
+	}
+
 	return n, err
 }
 
+// Close marks the write side as closed: this is h2conn's best-effort close
+// signal used by Server.Shutdown. It does not itself tear down the
+// underlying HTTP/2 stream — the handler is expected to notice the next
+// Write failing and return, which is what actually ends the stream.
 func (w *flushWrite) Close() error {
+	w.mu.Lock()
+	w.closed = true
+	w.mu.Unlock()
 	return nil
 }
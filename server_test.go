@@ -0,0 +1,82 @@
+package h2conn
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+
+	"golang.org/x/net/http2"
+)
+
+// TestShutdownRequestedUnblocksReadingHandler verifies that a handler
+// blocked in Read, which selects on Conn.ShutdownRequested, notices
+// Server.Shutdown and can return, letting Shutdown drain instead of
+// blocking until ctx expires.
+func TestShutdownRequestedUnblocksReadingHandler(t *testing.T) {
+	srv := &H2CServer{}
+	handlerReturned := make(chan struct{})
+	srv.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := srv.Accept(w, r)
+		if err != nil {
+			t.Errorf("server Accept: %v", err)
+			return
+		}
+		defer conn.Close()
+		defer close(handlerReturned)
+
+		buf := make([]byte, 16)
+		readDone := make(chan struct{})
+		go func() {
+			conn.Read(buf)
+			close(readDone)
+		}()
+
+		select {
+		case <-conn.ShutdownRequested():
+		case <-readDone:
+		}
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Listen: %v", err)
+	}
+	httpSrv := &http.Server{Handler: srv}
+	go httpSrv.Serve(ln)
+	defer httpSrv.Close()
+
+	httpClient := &http.Client{
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLSContext: func(ctx context.Context, network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.Dial(network, addr)
+			},
+		},
+	}
+
+	client := Client{Client: httpClient}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	conn, _, err := client.Connect(ctx, "http://"+ln.Addr().String()+"/")
+	if err != nil {
+		t.Fatalf("Connect: %v", err)
+	}
+	defer conn.Close()
+
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer shutdownCancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		t.Fatalf("Shutdown: %v (handler never noticed ShutdownRequested)", err)
+	}
+
+	select {
+	case <-handlerReturned:
+	default:
+		t.Fatal("Shutdown returned but handler goroutine never actually returned")
+	}
+}
@@ -0,0 +1,241 @@
+package h2conn
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// frameType identifies the kind of frame multiplexed onto the underlying
+// Conn by a Session.
+type frameType uint8
+
+const (
+	frameOpen frameType = iota
+	frameData
+	frameClose
+	frameWindowUpdate
+)
+
+// frameHeaderLen is the size, in bytes, of the header that precedes every
+// frame: 1 byte type, 4 bytes stream id, 4 bytes payload length.
+const frameHeaderLen = 9
+
+// defaultWindowSize is the number of bytes a Stream's peer is initially
+// allowed to send before it must wait for a WINDOW_UPDATE.
+const defaultWindowSize = 64 * 1024
+
+// maxFrameLength bounds the payload length a peer may declare for a single
+// frame, so that a corrupt or adversarial length field can't force an
+// unbounded allocation ahead of any application-level limit.
+const maxFrameLength = 16 * 1024 * 1024
+
+// Session multiplexes independently flow-controlled Streams over a single
+// h2conn Conn, so that many logical conversations can share one HTTP/2
+// stream without paying per-conversation HTTP/2 setup cost.
+type Session struct {
+	conn     *Conn
+	isClient bool
+
+	writeMu sync.Mutex
+
+	mu      sync.Mutex
+	streams map[uint32]*Stream
+	nextID  uint32
+	closed  bool
+	closeCh chan struct{}
+
+	acceptCh chan *Stream
+}
+
+// NewSession wraps conn with a Session. isClient must be true on the side
+// that called Connect and false on the side that called Accept, so that
+// both sides allocate disjoint stream ids (odd for clients, even for
+// servers, mirroring HTTP/2 itself).
+func NewSession(conn *Conn, isClient bool) *Session {
+	s := &Session{
+		conn:     conn,
+		isClient: isClient,
+		streams:  make(map[uint32]*Stream),
+		closeCh:  make(chan struct{}),
+		acceptCh: make(chan *Stream),
+	}
+	if isClient {
+		s.nextID = 1
+	} else {
+		s.nextID = 2
+	}
+	go s.readLoop()
+	return s
+}
+
+// Open starts a new Stream and announces it to the peer. It does not block
+// for the peer to acknowledge the stream; writes and reads on the returned
+// Stream are flow-controlled independently of any other stream.
+func (s *Session) Open() (*Stream, error) {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil, io.ErrClosedPipe
+	}
+	id := s.nextID
+	s.nextID += 2
+	st := newStream(s, id)
+	s.streams[id] = st
+	s.mu.Unlock()
+
+	if err := s.writeFrame(frameOpen, id, nil); err != nil {
+		return nil, err
+	}
+	return st, nil
+}
+
+// Accept blocks until the peer opens a new Stream, or the Session is
+// closed.
+func (s *Session) Accept() (*Stream, error) {
+	select {
+	case st, ok := <-s.acceptCh:
+		if !ok {
+			return nil, io.ErrClosedPipe
+		}
+		return st, nil
+	case <-s.closeCh:
+		return nil, io.ErrClosedPipe
+	}
+}
+
+// Close tears down every open Stream and the underlying Conn.
+func (s *Session) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	streams := s.streams
+	s.streams = nil
+	close(s.closeCh)
+	s.mu.Unlock()
+
+	for _, st := range streams {
+		st.closeWithError(io.ErrClosedPipe)
+	}
+	return s.conn.Close()
+}
+
+// writeFrame serializes and writes a single frame. It is safe for
+// concurrent use by multiple Streams.
+func (s *Session) writeFrame(t frameType, id uint32, payload []byte) error {
+	header := make([]byte, frameHeaderLen)
+	header[0] = byte(t)
+	binary.BigEndian.PutUint32(header[1:5], id)
+	binary.BigEndian.PutUint32(header[5:9], uint32(len(payload)))
+
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+
+	if _, err := s.conn.Write(header); err != nil {
+		return err
+	}
+	if len(payload) == 0 {
+		return nil
+	}
+	_, err := s.conn.Write(payload)
+	return err
+}
+
+// readLoop reads frames off the Conn until it errors or the Session is
+// closed, dispatching each frame to its Stream (creating one for inbound
+// OPEN frames).
+func (s *Session) readLoop() {
+	defer s.Close()
+
+	header := make([]byte, frameHeaderLen)
+	for {
+		if _, err := io.ReadFull(s.conn, header); err != nil {
+			return
+		}
+		t := frameType(header[0])
+		id := binary.BigEndian.Uint32(header[1:5])
+		length := binary.BigEndian.Uint32(header[5:9])
+		if length > maxFrameLength {
+			return
+		}
+
+		var payload []byte
+		if length > 0 {
+			payload = make([]byte, length)
+			if _, err := io.ReadFull(s.conn, payload); err != nil {
+				return
+			}
+		}
+
+		if err := s.dispatch(t, id, payload); err != nil {
+			return
+		}
+	}
+}
+
+func (s *Session) dispatch(t frameType, id uint32, payload []byte) error {
+	switch t {
+	case frameOpen:
+		s.mu.Lock()
+		if s.closed {
+			s.mu.Unlock()
+			return io.ErrClosedPipe
+		}
+		st := newStream(s, id)
+		s.streams[id] = st
+		s.mu.Unlock()
+
+		select {
+		case s.acceptCh <- st:
+		case <-s.closeCh:
+			return io.ErrClosedPipe
+		}
+		return nil
+
+	case frameData:
+		st := s.stream(id)
+		if st == nil {
+			return nil
+		}
+		return st.acceptData(payload)
+
+	case frameWindowUpdate:
+		st := s.stream(id)
+		if st == nil || len(payload) != 4 {
+			return nil
+		}
+		st.grantSendWindow(binary.BigEndian.Uint32(payload))
+		return nil
+
+	case frameClose:
+		st := s.stream(id)
+		if st == nil {
+			return nil
+		}
+		st.closeWithError(io.EOF)
+		s.removeStream(id)
+		return nil
+
+	default:
+		return fmt.Errorf("h2conn: unknown frame type %d", t)
+	}
+}
+
+func (s *Session) stream(id uint32) *Stream {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.streams[id]
+}
+
+// removeStream drops id from the stream table, so that further frames
+// referencing it (e.g. stray DATA frames from a peer that hasn't yet seen
+// our CLOSE) are ignored instead of leaking into a zombie Stream forever.
+func (s *Session) removeStream(id uint32) {
+	s.mu.Lock()
+	delete(s.streams, id)
+	s.mu.Unlock()
+}
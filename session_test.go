@@ -0,0 +1,138 @@
+package h2conn
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+)
+
+// pipeConn adapts a net.Pipe half into the Conn type Session expects,
+// without involving any HTTP machinery.
+func pipeConn(t *testing.T) (*Conn, *Conn) {
+	t.Helper()
+	a, b := net.Pipe()
+	ca := newConn(context.Background(), a, a, connOptions{})
+	cb := newConn(context.Background(), b, b, connOptions{})
+	return ca, cb
+}
+
+func TestStreamCloseRemovesFromSessionLocally(t *testing.T) {
+	ca, cb := pipeConn(t)
+	defer ca.Close()
+	defer cb.Close()
+
+	clientSession := NewSession(ca, true)
+	serverSession := NewSession(cb, false)
+	defer clientSession.Close()
+	defer serverSession.Close()
+
+	st, err := clientSession.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := serverSession.Accept(); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	if err := st.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	// Close removes the stream from the session's table immediately; give
+	// the readLoop goroutine a moment in case of any async bookkeeping.
+	deadline := time.After(time.Second)
+	for {
+		clientSession.mu.Lock()
+		_, present := clientSession.streams[st.id]
+		clientSession.mu.Unlock()
+		if !present {
+			return
+		}
+		select {
+		case <-deadline:
+			t.Fatalf("stream %d was not removed from Session.streams after Close", st.id)
+		case <-time.After(time.Millisecond):
+		}
+	}
+}
+
+func TestSessionRejectsOversizedFrameLength(t *testing.T) {
+	ca, cb := pipeConn(t)
+	defer ca.Close()
+	defer cb.Close()
+
+	serverSession := NewSession(cb, false)
+	defer serverSession.Close()
+
+	// Hand-craft a DATA frame header claiming a payload far beyond
+	// maxFrameLength, and confirm the session tears itself down instead of
+	// attempting the allocation.
+	if err := clientWriteOversizedFrame(ca); err != nil {
+		t.Fatalf("write oversized frame: %v", err)
+	}
+
+	select {
+	case <-serverSession.closeCh:
+	case <-time.After(time.Second):
+		t.Fatal("session did not close after receiving an oversized frame length")
+	}
+}
+
+func clientWriteOversizedFrame(c *Conn) error {
+	header := make([]byte, frameHeaderLen)
+	header[0] = byte(frameData)
+	header[5] = 0xff
+	header[6] = 0xff
+	header[7] = 0xff
+	header[8] = 0xff
+	_, err := c.Write(header)
+	return err
+}
+
+func TestStreamWriteUnblocksOnSessionClose(t *testing.T) {
+	ca, cb := pipeConn(t)
+	defer ca.Close()
+	defer cb.Close()
+
+	clientSession := NewSession(ca, true)
+	serverSession := NewSession(cb, false)
+	defer serverSession.Close()
+
+	st, err := clientSession.Open()
+	if err != nil {
+		t.Fatalf("Open: %v", err)
+	}
+	if _, err := serverSession.Accept(); err != nil {
+		t.Fatalf("Accept: %v", err)
+	}
+
+	// Exhaust the send window directly, as if every byte of credit had
+	// already been spent, so the next Write has to block on sendCond.
+	st.sendWindowMu.Lock()
+	st.sendWindow = 0
+	st.sendWindowMu.Unlock()
+
+	writeErr := make(chan error, 1)
+	go func() {
+		_, err := st.Write([]byte("x"))
+		writeErr <- err
+	}()
+
+	// Give the goroutine a chance to actually block in reserveSendWindow
+	// before tearing the session down.
+	time.Sleep(10 * time.Millisecond)
+
+	if err := clientSession.Close(); err != nil {
+		t.Fatalf("Close: %v", err)
+	}
+
+	select {
+	case err := <-writeErr:
+		if err == nil {
+			t.Fatal("expected Write to return an error after Session.Close, got nil")
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Write did not unblock after Session.Close")
+	}
+}
@@ -0,0 +1,147 @@
+package h2conn
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+)
+
+// Stream is one logical, independently flow-controlled conversation
+// multiplexed over a Session. Stream implements io.ReadWriteCloser.
+type Stream struct {
+	session *Session
+	id      uint32
+
+	readMu   sync.Mutex
+	readCond *sync.Cond
+	readBuf  bytes.Buffer
+	readErr  error
+	consumed uint32
+
+	sendWindow   uint32
+	sendWindowMu sync.Mutex
+	sendCond     *sync.Cond
+	sendErr      error
+}
+
+func newStream(s *Session, id uint32) *Stream {
+	st := &Stream{
+		session:    s,
+		id:         id,
+		sendWindow: defaultWindowSize,
+	}
+	st.readCond = sync.NewCond(&st.readMu)
+	st.sendCond = sync.NewCond(&st.sendWindowMu)
+	return st
+}
+
+// Write sends data to the peer, blocking as needed for send-window credit
+// so that a slow reader on this Stream applies backpressure only to this
+// Stream, not to the rest of the Session.
+func (st *Stream) Write(p []byte) (int, error) {
+	written := 0
+	for len(p) > 0 {
+		chunk, err := st.reserveSendWindow(p)
+		if err != nil {
+			return written, err
+		}
+		if err := st.session.writeFrame(frameData, st.id, chunk); err != nil {
+			return written, err
+		}
+		written += len(chunk)
+		p = p[len(chunk):]
+	}
+	return written, nil
+}
+
+// reserveSendWindow blocks until at least one byte of send-window credit is
+// available, then reserves up to len(p) bytes of it.
+func (st *Stream) reserveSendWindow(p []byte) ([]byte, error) {
+	st.sendWindowMu.Lock()
+	defer st.sendWindowMu.Unlock()
+
+	for st.sendWindow == 0 && st.sendErr == nil {
+		st.sendCond.Wait()
+	}
+	if st.sendErr != nil {
+		return nil, st.sendErr
+	}
+	n := uint32(len(p))
+	if n > st.sendWindow {
+		n = st.sendWindow
+	}
+	st.sendWindow -= n
+	return p[:n], nil
+}
+
+// grantSendWindow credits n bytes back to the send window, in response to a
+// WINDOW_UPDATE frame from the peer.
+func (st *Stream) grantSendWindow(n uint32) {
+	st.sendWindowMu.Lock()
+	st.sendWindow += n
+	st.sendWindowMu.Unlock()
+	st.sendCond.Broadcast()
+}
+
+// Read reads data sent by the peer, blocking until data is available.
+func (st *Stream) Read(p []byte) (int, error) {
+	st.readMu.Lock()
+	for st.readBuf.Len() == 0 && st.readErr == nil {
+		st.readCond.Wait()
+	}
+	n, _ := st.readBuf.Read(p)
+	err := st.readErr
+	st.readMu.Unlock()
+
+	if n > 0 {
+		st.consumed += uint32(n)
+		if st.consumed >= defaultWindowSize/2 {
+			credit := st.consumed
+			st.consumed = 0
+			update := make([]byte, 4)
+			binary.BigEndian.PutUint32(update, credit)
+			_ = st.session.writeFrame(frameWindowUpdate, st.id, update)
+		}
+		return n, nil
+	}
+	return 0, err
+}
+
+// acceptData appends data received from the peer to the Stream's read
+// buffer, waking any blocked Read.
+func (st *Stream) acceptData(data []byte) error {
+	st.readMu.Lock()
+	st.readBuf.Write(data)
+	st.readMu.Unlock()
+	st.readCond.Broadcast()
+	return nil
+}
+
+// closeWithError unblocks any pending Read with err and marks the Stream
+// closed for subsequent reads, and unblocks any Write waiting on send-window
+// credit that will now never arrive.
+func (st *Stream) closeWithError(err error) {
+	st.readMu.Lock()
+	if st.readErr == nil {
+		st.readErr = err
+	}
+	st.readMu.Unlock()
+	st.readCond.Broadcast()
+
+	st.sendWindowMu.Lock()
+	if st.sendErr == nil {
+		st.sendErr = err
+	}
+	st.sendWindowMu.Unlock()
+	st.sendCond.Broadcast()
+}
+
+// Close announces to the peer that this Stream is done and releases its
+// local resources, including removing it from the Session's stream table.
+// It does not close the underlying Session.
+func (st *Stream) Close() error {
+	st.closeWithError(io.EOF)
+	st.session.removeStream(st.id)
+	return st.session.writeFrame(frameClose, st.id, nil)
+}